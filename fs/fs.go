@@ -0,0 +1,180 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/ulule/gostorages"
+)
+
+func init() {
+	gostorages.Register("file", open)
+}
+
+// open builds a Storage from a DSN such as "file:///var/data", for use
+// with gostorages.Open.
+func open(ctx context.Context, dsn *url.URL) (gostorages.Storage, error) {
+	return NewStorage(Config{Root: dsn.Path}), nil
+}
+
+// Storage is a filesystem storage.
+type Storage struct {
+	root string
+}
+
+// NewStorage returns a new filesystem storage.
+func NewStorage(cfg Config) *Storage {
+	return &Storage{root: cfg.Root}
+}
+
+// Config is the configuration for Storage.
+type Config struct {
+	Root string
+}
+
+func (fs *Storage) abs(path string) string {
+	return filepath.Join(fs.root, path)
+}
+
+// Save saves content to path.
+func (fs *Storage) Save(ctx context.Context, content io.Reader, path string) error {
+	abs := fs.abs(path)
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return err
+	}
+
+	w, err := os.Create(abs)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if _, err := io.Copy(w, content); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Stat returns path metadata.
+func (fs *Storage) Stat(ctx context.Context, path string) (*gostorages.Stat, error) {
+	fi, err := os.Stat(fs.abs(path))
+	if os.IsNotExist(err) {
+		return nil, gostorages.ErrNotExist
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &gostorages.Stat{
+		ModifiedTime: fi.ModTime(),
+		Size:         fi.Size(),
+	}, nil
+}
+
+// Open opens path for reading.
+func (fs *Storage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	f, err := os.Open(fs.abs(path))
+	if os.IsNotExist(err) {
+		return nil, gostorages.ErrNotExist
+	}
+	return f, err
+}
+
+// Delete deletes path.
+func (fs *Storage) Delete(ctx context.Context, path string) error {
+	return os.Remove(fs.abs(path))
+}
+
+// OpenWithStat opens path for reading with file stats.
+func (fs *Storage) OpenWithStat(ctx context.Context, path string) (io.ReadCloser, *gostorages.Stat, error) {
+	f, err := os.Open(fs.abs(path))
+	if os.IsNotExist(err) {
+		return nil, nil, gostorages.ErrNotExist
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, &gostorages.Stat{
+		ModifiedTime: stat.ModTime(),
+		Size:         stat.Size(),
+	}, nil
+}
+
+// List enumerates the entries stored under prefix, walking the directory
+// tree. Intermediate directories are returned as entries with IsPrefix set
+// when opts.Recursive is false, mirroring the "directory" common prefixes
+// returned by S3 with a delimiter.
+func (fs *Storage) List(ctx context.Context, prefix string, opts gostorages.ListOptions) ([]gostorages.Entry, string, error) {
+	if _, err := os.Stat(fs.root); os.IsNotExist(err) {
+		return nil, "", gostorages.ErrNotExist
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	root := fs.abs(prefix)
+	info, err := os.Stat(root)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	} else if err != nil {
+		return nil, "", err
+	}
+	if !info.IsDir() {
+		return nil, "", nil
+	}
+
+	var entries []gostorages.Entry
+
+	err = filepath.Walk(root, func(abs string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if abs == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(filepath.Join(prefix, rel))
+
+		if fi.IsDir() {
+			if opts.Recursive {
+				// Always descend: a directory's own key sorting at or
+				// before StartAfter says nothing about the files nested
+				// under it, which may still sort after it.
+				return nil
+			}
+			key += "/"
+			if opts.StartAfter != "" && key <= opts.StartAfter {
+				return filepath.SkipDir
+			}
+			entries = append(entries, gostorages.Entry{Path: key, IsPrefix: true})
+			return filepath.SkipDir
+		}
+
+		if opts.StartAfter != "" && key <= opts.StartAfter {
+			return nil
+		}
+
+		entries = append(entries, gostorages.Entry{
+			Path:         key,
+			Size:         fi.Size(),
+			ModifiedTime: fi.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if opts.MaxKeys > 0 && int64(len(entries)) > opts.MaxKeys {
+		token := entries[opts.MaxKeys-1].Path
+		return entries[:opts.MaxKeys], token, nil
+	}
+
+	return entries, "", nil
+}
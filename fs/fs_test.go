@@ -0,0 +1,160 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ulule/gostorages"
+)
+
+func Test(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostorages-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := NewStorage(Config{Root: dir})
+	ctx := context.Background()
+
+	if _, err = storage.Stat(ctx, "doesnotexist"); !errors.Is(err, gostorages.ErrNotExist) {
+		t.Errorf("expected not exists, got %v", err)
+	}
+
+	before := time.Now()
+	if err := storage.Save(ctx, bytes.NewBufferString("hello"), "world"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	stat, err := storage.Stat(ctx, "world")
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if stat.Size != 5 {
+		t.Errorf("expected size to be %d, got %d", 5, stat.Size)
+	}
+	if stat.ModifiedTime.Before(before) {
+		t.Errorf("expected modtime to be after %v, got %v", before, stat.ModifiedTime)
+	}
+	if stat.ModifiedTime.After(now) {
+		t.Errorf("expected modtime to be before %v, got %v", now, stat.ModifiedTime)
+	}
+}
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "gostorages-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStorage(Config{Root: dir})
+}
+
+func TestListRecursive(t *testing.T) {
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	for _, path := range []string{"a", "b", "dir/c", "dir/sub/d"} {
+		if err := storage.Save(ctx, bytes.NewBufferString(path), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, token, err := storage.List(ctx, "", gostorages.ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		t.Errorf("expected no continuation token, got %q", token)
+	}
+	if len(entries) != 4 {
+		t.Errorf("expected 4 entries, got %d: %+v", len(entries), entries)
+	}
+
+	// Paginate a page at a time and ensure the loop actually terminates
+	// and visits every entry exactly once.
+	seen := map[string]bool{}
+	startAfter := ""
+	for i := 0; i < len(entries)+1; i++ {
+		page, next, err := storage.List(ctx, "", gostorages.ListOptions{Recursive: true, MaxKeys: 1, StartAfter: startAfter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, entry := range page {
+			if seen[entry.Path] {
+				t.Fatalf("entry %q returned more than once", entry.Path)
+			}
+			seen[entry.Path] = true
+		}
+		if next == "" {
+			break
+		}
+		startAfter = next
+	}
+	if len(seen) != 4 {
+		t.Errorf("expected to see 4 entries while paginating, got %d: %+v", len(seen), seen)
+	}
+}
+
+func TestListNonRecursive(t *testing.T) {
+	storage := newTestStorage(t)
+	ctx := context.Background()
+
+	for _, path := range []string{"a", "b", "dir/c"} {
+		if err := storage.Save(ctx, bytes.NewBufferString(path), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, _, err := storage.List(ctx, "", gostorages.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 2 files and 1 common prefix, got %d: %+v", len(entries), entries)
+	}
+
+	var sawPrefix bool
+	for _, entry := range entries {
+		if entry.Path == "dir/" {
+			sawPrefix = true
+			if !entry.IsPrefix {
+				t.Errorf("expected dir/ to be a prefix entry")
+			}
+		}
+	}
+	if !sawPrefix {
+		t.Errorf("expected a dir/ common prefix entry, got %+v", entries)
+	}
+
+	// Regression test: a repeated call using the token from a page that
+	// included a common prefix must make forward progress, not return the
+	// same page forever.
+	page, token, err := storage.List(ctx, "", gostorages.ListOptions{MaxKeys: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || token == "" {
+		t.Fatalf("expected a single-entry page with a continuation token, got %+v token=%q", page, token)
+	}
+
+	next, nextToken, err := storage.List(ctx, "", gostorages.ListOptions{MaxKeys: 1, StartAfter: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 1 {
+		t.Fatalf("expected a single entry on the next page, got %+v", next)
+	}
+	if next[0].Path == page[0].Path {
+		t.Fatalf("second page repeated the first entry %q instead of advancing", page[0].Path)
+	}
+	if nextToken == token {
+		t.Fatalf("continuation token did not advance: still %q", token)
+	}
+}
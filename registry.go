@@ -0,0 +1,47 @@
+package gostorages
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// OpenFunc constructs a Storage from a parsed DSN. Backend packages
+// register an OpenFunc for the URL scheme they handle via Register.
+type OpenFunc func(ctx context.Context, dsn *url.URL) (Storage, error)
+
+var registry = struct {
+	mu      sync.RWMutex
+	openers map[string]OpenFunc
+}{openers: make(map[string]OpenFunc)}
+
+// Register associates an OpenFunc with a URL scheme, so that Open can
+// dispatch DSNs using that scheme to the right backend. Backend packages
+// call Register from an init function.
+func Register(scheme string, opener OpenFunc) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.openers[scheme] = opener
+}
+
+// Open parses dsn, e.g. "s3://bucket/prefix?region=..." or
+// "file:///var/data", and returns the Storage backend registered for its
+// scheme. This lets applications select a storage driver from a single
+// connection string, such as a STORAGE_URL environment variable, instead
+// of hand-wiring backend-specific Config structs.
+func Open(ctx context.Context, dsn string) (Storage, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.mu.RLock()
+	opener, ok := registry.openers[u.Scheme]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("gostorages: no storage registered for scheme %q", u.Scheme)
+	}
+
+	return opener(ctx, u)
+}
@@ -6,11 +6,17 @@ import (
 	"io"
 	"mime"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -18,35 +24,154 @@ import (
 	"github.com/ulule/gostorages"
 )
 
+func init() {
+	gostorages.Register("s3", open)
+}
+
+// open builds a Storage from a DSN such as
+// "s3://bucket?region=eu-west-1&endpoint=http://localhost:4566&force_path_style=true",
+// for use with gostorages.Open. Credentials are never read from the DSN:
+// a STORAGE_URL-style connection string routinely ends up in shell
+// history, process listings, and logs, so this always resolves
+// credentials through Config.CredentialsProvider or the default AWS
+// credential chain (environment, shared config file, instance role, web
+// identity, SSO, ...), optionally scoped by a "profile" query parameter.
+func open(ctx context.Context, dsn *url.URL) (gostorages.Storage, error) {
+	cfg := Config{
+		Bucket: dsn.Host,
+		Prefix: strings.Trim(dsn.Path, "/"),
+	}
+
+	q := dsn.Query()
+	cfg.Region = q.Get("region")
+	cfg.Endpoint = q.Get("endpoint")
+	cfg.Profile = q.Get("profile")
+	if v, err := strconv.ParseBool(q.Get("force_path_style")); err == nil {
+		cfg.S3ForcePathStyle = v
+	}
+	if v, err := strconv.ParseBool(q.Get("disable_ssl")); err == nil {
+		cfg.DisableSSL = v
+	}
+
+	return NewStorage(cfg)
+}
+
 // Storage is a s3 storage.
 type Storage struct {
-	bucket   string
-	s3       *s3.S3
-	uploader *s3manager.Uploader
+	bucket           string
+	prefix           string
+	s3               *s3.S3
+	uploader         *s3manager.Uploader
+	saveOptions      SaveOptions
+	disableMultipart bool
+}
+
+// WithPrefix returns a copy of s scoped to an additional prefix nested
+// under its current one, for sharing a single bucket across environments
+// or tenants without allocating a bucket per use case.
+func (s *Storage) WithPrefix(prefix string) *Storage {
+	clone := *s
+	clone.prefix = joinPrefix(s.prefix, prefix)
+	return &clone
+}
+
+func joinPrefix(base, prefix string) string {
+	base = strings.Trim(base, "/")
+	prefix = strings.Trim(prefix, "/")
+	switch {
+	case base == "":
+		return prefix
+	case prefix == "":
+		return base
+	default:
+		return base + "/" + prefix
+	}
+}
+
+// key prepends the storage's prefix to path.
+func (s *Storage) key(path string) string {
+	if s.prefix == "" {
+		return path
+	}
+	return s.prefix + "/" + strings.TrimPrefix(path, "/")
+}
+
+// stripPrefix removes the storage's prefix from a key returned by List.
+func (s *Storage) stripPrefix(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, s.prefix+"/")
 }
 
 // NewStorage returns a new Storage.
+//
+// When cfg.AccessKeyID is empty, credentials are taken from
+// cfg.CredentialsProvider if set, otherwise from the default AWS
+// credential chain (environment, shared config/credentials file, EC2/ECS
+// instance role, web identity, SSO, ...), optionally scoped to cfg.Profile.
 func NewStorage(cfg Config) (*Storage, error) {
 	awscfg := &aws.Config{
-		Credentials: credentials.NewStaticCredentialsFromCreds(credentials.Value{
+		Region: aws.String(cfg.Region),
+	}
+	switch {
+	case cfg.AccessKeyID != "":
+		awscfg.Credentials = credentials.NewStaticCredentialsFromCreds(credentials.Value{
 			AccessKeyID:     cfg.AccessKeyID,
 			SecretAccessKey: cfg.SecretAccessKey,
-		}),
-		Region: aws.String(cfg.Region),
+		})
+	case cfg.CredentialsProvider != nil:
+		awscfg.Credentials = credentials.NewCredentials(cfg.CredentialsProvider)
 	}
 	if cfg.Endpoint != "" {
 		awscfg.Endpoint = &(cfg.Endpoint)
 	}
-	s, err := session.NewSession(awscfg)
+	if cfg.S3ForcePathStyle {
+		awscfg.S3ForcePathStyle = aws.Bool(true)
+	}
+	if cfg.DisableSSL {
+		awscfg.DisableSSL = aws.Bool(true)
+	}
+
+	opts := session.Options{Config: *awscfg}
+	if cfg.Profile != "" {
+		opts.Profile = cfg.Profile
+		opts.SharedConfigState = session.SharedConfigEnable
+	}
+	s, err := session.NewSessionWithOptions(opts)
 	if err != nil {
 		return nil, err
 	}
 
+	storage := NewStorageFromSession(s, cfg.Bucket, cfg.SaveOptions, cfg.Uploader)
+	storage.prefix = strings.Trim(cfg.Prefix, "/")
+	return storage, nil
+}
+
+// NewStorageFromSession returns a new Storage using a pre-built AWS
+// session, for callers that already manage their AWS configuration
+// centrally.
+func NewStorageFromSession(s *session.Session, bucket string, saveOpts SaveOptions, uploaderOpts UploaderOptions) *Storage {
+	uploader := s3manager.NewUploader(s, func(u *s3manager.Uploader) {
+		if uploaderOpts.PartSize > 0 {
+			u.PartSize = uploaderOpts.PartSize
+		}
+		if uploaderOpts.Concurrency > 0 {
+			u.Concurrency = uploaderOpts.Concurrency
+		}
+		if uploaderOpts.MaxUploadParts > 0 {
+			u.MaxUploadParts = uploaderOpts.MaxUploadParts
+		}
+		u.LeavePartsOnError = uploaderOpts.LeavePartsOnError
+	})
+
 	return &Storage{
-		bucket:   cfg.Bucket,
-		s3:       s3.New(s),
-		uploader: s3manager.NewUploader(s),
-	}, nil
+		bucket:           bucket,
+		s3:               s3.New(s),
+		uploader:         uploader,
+		saveOptions:      saveOpts,
+		disableMultipart: uploaderOpts.DisableMultipart,
+	}
 }
 
 // Config is the configuration for Storage.
@@ -56,15 +181,123 @@ type Config struct {
 	Endpoint        string
 	Region          string
 	SecretAccessKey string
+
+	// Prefix is transparently prepended to every path passed to Save,
+	// Open, Stat, Delete, OpenWithStat and List, and stripped back out of
+	// List results. It scopes the Storage to a sub-tree of the bucket,
+	// e.g. to share a single bucket across environments or tenants.
+	Prefix string
+
+	// CredentialsProvider supplies AWS credentials explicitly, e.g. from
+	// an IAM instance/task role, web identity (IRSA), or SSO. It is used
+	// when AccessKeyID is empty, falling back to the default credential
+	// chain when it is also nil.
+	CredentialsProvider credentials.Provider
+
+	// Profile selects a named profile from ~/.aws/credentials when
+	// falling back to the default credential chain.
+	Profile string
+
+	// S3ForcePathStyle forces path-style addressing (bucket in the URL
+	// path rather than as a subdomain), required by MinIO, LocalStack,
+	// Ceph and most other S3-compatible endpoints.
+	S3ForcePathStyle bool
+
+	// DisableSSL disables HTTPS, for talking to a local S3-compatible
+	// endpoint over plain HTTP.
+	DisableSSL bool
+
+	// SaveOptions are the default options applied by Save. Use
+	// SaveWithOptions to override them for a single call.
+	SaveOptions SaveOptions
+
+	// Uploader tunes the underlying multipart uploader used by Save and
+	// SaveWithOptions.
+	Uploader UploaderOptions
+}
+
+// UploaderOptions tunes the underlying s3manager.Uploader used by Save
+// and SaveWithOptions.
+type UploaderOptions struct {
+	// PartSize is the size in bytes of each multipart upload part. It
+	// defaults to s3manager.DefaultUploadPartSize when zero.
+	PartSize int64
+	// Concurrency is the number of parts uploaded in parallel. It
+	// defaults to s3manager.DefaultUploadConcurrency when zero.
+	Concurrency int
+	// LeavePartsOnError disables the automatic abort of a failed
+	// multipart upload, leaving uploaded parts in the bucket for manual
+	// inspection or cleanup.
+	LeavePartsOnError bool
+	// MaxUploadParts caps the number of parts a multipart upload may be
+	// split into. It defaults to s3manager.MaxUploadParts when zero.
+	MaxUploadParts int
+	// DisableMultipart falls back to a single PutObject call instead of
+	// a multipart upload, reducing overhead for small-object workloads.
+	DisableMultipart bool
+}
+
+// ACLUnset omits the x-amz-acl header entirely, for buckets that have
+// ACLs disabled (the modern S3 default) or that rely on bucket policies
+// instead of canned ACLs.
+const ACLUnset = ""
+
+// SaveOptions configures how an object is written by SaveWithOptions.
+type SaveOptions struct {
+	// ACL is the canned ACL applied to the object, e.g.
+	// s3.ObjectCannedACLPublicRead. Leave it as ACLUnset to omit the
+	// header entirely.
+	ACL string
+	// ServerSideEncryption selects the server-side encryption mode, e.g.
+	// s3.ServerSideEncryptionAes256 or s3.ServerSideEncryptionAwsKms.
+	ServerSideEncryption string
+	// SSEKMSKeyID is the KMS key ID used when ServerSideEncryption is
+	// s3.ServerSideEncryptionAwsKms.
+	SSEKMSKeyID string
+	// StorageClass is the S3 storage class, e.g.
+	// s3.StorageClassStandardIa or s3.StorageClassGlacier.
+	StorageClass string
+	// CacheControl sets the Cache-Control header on the object.
+	CacheControl string
+	// Metadata is attached as user-defined metadata on the object.
+	Metadata map[string]string
 }
 
-// Save saves content to path.
+// Save saves content to path, applying the Storage's default SaveOptions.
 func (s *Storage) Save(ctx context.Context, content io.Reader, path string) error {
+	return s.SaveWithOptions(ctx, content, path, s.saveOptions)
+}
+
+// SaveWithOptions saves content to path, overriding the Storage's default
+// SaveOptions for this call. overrides are applied to the underlying
+// s3manager.UploadInput last, letting callers set knobs this package does
+// not otherwise expose, such as ContentMD5 or Tagging.
+func (s *Storage) SaveWithOptions(ctx context.Context, content io.Reader, path string, opts SaveOptions, overrides ...func(*s3manager.UploadInput)) error {
 	input := &s3manager.UploadInput{
-		ACL:    aws.String(s3.ObjectCannedACLPublicRead),
 		Body:   content,
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
+		Key:    aws.String(s.key(path)),
+	}
+	if opts.ACL != ACLUnset {
+		input.ACL = aws.String(opts.ACL)
+	}
+	if opts.ServerSideEncryption != "" {
+		input.ServerSideEncryption = aws.String(opts.ServerSideEncryption)
+	}
+	if opts.SSEKMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(opts.SSEKMSKeyID)
+	}
+	if opts.StorageClass != "" {
+		input.StorageClass = aws.String(opts.StorageClass)
+	}
+	if opts.CacheControl != "" {
+		input.CacheControl = aws.String(opts.CacheControl)
+	}
+	if len(opts.Metadata) > 0 {
+		input.Metadata = make(map[string]*string, len(opts.Metadata))
+		for k, v := range opts.Metadata {
+			input.Metadata[k] = aws.String(v)
+		}
 	}
 
 	contenttype := mime.TypeByExtension(filepath.Ext(path)) // first, detect content type from extension
@@ -81,15 +314,85 @@ func (s *Storage) Save(ctx context.Context, content io.Reader, path string) erro
 		input.ContentType = aws.String(contenttype)
 	}
 
+	for _, override := range overrides {
+		override(input)
+	}
+
+	if s.disableMultipart {
+		return s.putObject(ctx, input)
+	}
+
 	_, err := s.uploader.UploadWithContext(ctx, input)
 	return err
 }
 
+// putObject uploads input with a single PutObject call instead of a
+// multipart upload, used when UploaderOptions.DisableMultipart is set.
+func (s *Storage) putObject(ctx context.Context, input *s3manager.UploadInput) error {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return err
+	}
+
+	putInput := putObjectInputFromUpload(input)
+	putInput.Body = bytes.NewReader(data)
+
+	_, err = s.s3.PutObjectWithContext(ctx, putInput)
+	return err
+}
+
+// putObjectInputFromUpload copies every field PutObjectInput shares with
+// UploadInput, so that overrides applied to an UploadInput by
+// SaveWithOptions are honored the same way whether or not multipart upload
+// is disabled. Body is left unset; the caller provides it, since
+// PutObjectInput needs an io.ReadSeeker rather than the io.Reader UploadInput
+// carries. ContentLength is also left unset: PutObjectWithContext derives it
+// from the io.ReadSeeker body.
+func putObjectInputFromUpload(input *s3manager.UploadInput) *s3.PutObjectInput {
+	return &s3.PutObjectInput{
+		ACL:                       input.ACL,
+		Bucket:                    input.Bucket,
+		BucketKeyEnabled:          input.BucketKeyEnabled,
+		CacheControl:              input.CacheControl,
+		ChecksumAlgorithm:         input.ChecksumAlgorithm,
+		ChecksumCRC32:             input.ChecksumCRC32,
+		ChecksumCRC32C:            input.ChecksumCRC32C,
+		ChecksumSHA1:              input.ChecksumSHA1,
+		ChecksumSHA256:            input.ChecksumSHA256,
+		ContentDisposition:        input.ContentDisposition,
+		ContentEncoding:           input.ContentEncoding,
+		ContentLanguage:           input.ContentLanguage,
+		ContentMD5:                input.ContentMD5,
+		ContentType:               input.ContentType,
+		ExpectedBucketOwner:       input.ExpectedBucketOwner,
+		Expires:                   input.Expires,
+		GrantFullControl:          input.GrantFullControl,
+		GrantRead:                 input.GrantRead,
+		GrantReadACP:              input.GrantReadACP,
+		GrantWriteACP:             input.GrantWriteACP,
+		Key:                       input.Key,
+		Metadata:                  input.Metadata,
+		ObjectLockLegalHoldStatus: input.ObjectLockLegalHoldStatus,
+		ObjectLockMode:            input.ObjectLockMode,
+		ObjectLockRetainUntilDate: input.ObjectLockRetainUntilDate,
+		RequestPayer:              input.RequestPayer,
+		SSECustomerAlgorithm:      input.SSECustomerAlgorithm,
+		SSECustomerKey:            input.SSECustomerKey,
+		SSECustomerKeyMD5:         input.SSECustomerKeyMD5,
+		SSEKMSEncryptionContext:   input.SSEKMSEncryptionContext,
+		SSEKMSKeyId:               input.SSEKMSKeyId,
+		ServerSideEncryption:      input.ServerSideEncryption,
+		StorageClass:              input.StorageClass,
+		Tagging:                   input.Tagging,
+		WebsiteRedirectLocation:   input.WebsiteRedirectLocation,
+	}
+}
+
 // Stat returns path metadata.
 func (s *Storage) Stat(ctx context.Context, path string) (*gostorages.Stat, error) {
 	input := &s3.HeadObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
+		Key:    aws.String(s.key(path)),
 	}
 	out, err := s.s3.HeadObjectWithContext(ctx, input)
 
@@ -109,7 +412,7 @@ func (s *Storage) Stat(ctx context.Context, path string) (*gostorages.Stat, erro
 func (s *Storage) Open(ctx context.Context, path string) (io.ReadCloser, error) {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
+		Key:    aws.String(s.key(path)),
 	}
 	out, err := s.s3.GetObjectWithContext(ctx, input)
 	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
@@ -124,17 +427,188 @@ func (s *Storage) Open(ctx context.Context, path string) (io.ReadCloser, error)
 func (s *Storage) Delete(ctx context.Context, path string) error {
 	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
+		Key:    aws.String(s.key(path)),
 	}
 	_, err := s.s3.DeleteObjectWithContext(ctx, input)
 	return err
 }
 
+// defaultSignedURLExpiry is used by SignedURL and SignedUploadURL when
+// SignedURLOptions.Expiry is zero.
+const defaultSignedURLExpiry = 15 * time.Minute
+
+// SignedURLMethod is the HTTP method a signed URL is valid for.
+type SignedURLMethod string
+
+// Supported SignedURLMethod values.
+const (
+	SignedURLMethodGet  SignedURLMethod = http.MethodGet
+	SignedURLMethodPut  SignedURLMethod = http.MethodPut
+	SignedURLMethodHead SignedURLMethod = http.MethodHead
+)
+
+// SignedURLOptions configures SignedURL and SignedUploadURL.
+type SignedURLOptions struct {
+	// Expiry is how long the URL remains valid. Defaults to 15 minutes
+	// when zero.
+	Expiry time.Duration
+	// Method is the HTTP method the URL is valid for. Defaults to GET
+	// for SignedURL. SignedUploadURL ignores it and always signs PUT.
+	Method SignedURLMethod
+	// ResponseContentDisposition sets the Content-Disposition header
+	// returned when a GET URL is fetched. HEAD and PUT URLs ignore it.
+	ResponseContentDisposition string
+	// ContentType sets the content type expected of a PUT upload, or
+	// returned by a GET download.
+	ContentType string
+}
+
+// SignedURL returns a presigned URL for downloading or inspecting path
+// directly from the client, without proxying bytes through the
+// application or making the object public.
+func (s *Storage) SignedURL(ctx context.Context, path string, opts SignedURLOptions) (string, error) {
+	method := opts.Method
+	if method == "" {
+		method = SignedURLMethodGet
+	}
+
+	var req *request.Request
+	switch method {
+	case SignedURLMethodGet:
+		input := &s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(s.key(path)),
+		}
+		if opts.ResponseContentDisposition != "" {
+			input.ResponseContentDisposition = aws.String(opts.ResponseContentDisposition)
+		}
+		if opts.ContentType != "" {
+			input.ResponseContentType = aws.String(opts.ContentType)
+		}
+		req, _ = s.s3.GetObjectRequest(input)
+	case SignedURLMethodHead:
+		req, _ = s.s3.HeadObjectRequest(&s3.HeadObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(s.key(path)),
+		})
+	case SignedURLMethodPut:
+		return s.SignedUploadURL(ctx, path, opts)
+	default:
+		return "", errors.Errorf("s3: unsupported signed URL method %q", method)
+	}
+
+	req.SetContext(ctx)
+	return req.Presign(expiryOrDefault(opts.Expiry))
+}
+
+// SignedUploadURL returns a presigned URL clients can PUT content to
+// directly, removing the need to proxy large uploads through the
+// application.
+func (s *Storage) SignedUploadURL(ctx context.Context, path string, opts SignedURLOptions) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(path)),
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+
+	req, _ := s.s3.PutObjectRequest(input)
+	req.SetContext(ctx)
+	return req.Presign(expiryOrDefault(opts.Expiry))
+}
+
+func expiryOrDefault(expiry time.Duration) time.Duration {
+	if expiry == 0 {
+		return defaultSignedURLExpiry
+	}
+	return expiry
+}
+
+// List enumerates the entries stored under prefix using ListObjectsV2.
+// When opts.Recursive is false, a "/" delimiter is used and intermediate
+// "directories" are returned as entries with IsPrefix set. The returned
+// string is the Path of the last entry emitted, to pass back as
+// opts.StartAfter to resume after it, or empty once the listing is
+// exhausted — the same plain-key token contract the fs backend uses, so
+// code written against gostorages.Lister works against either.
+func (s *Storage) List(ctx context.Context, prefix string, opts gostorages.ListOptions) ([]gostorages.Entry, string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.key(prefix)),
+	}
+	if !opts.Recursive {
+		input.Delimiter = aws.String("/")
+	}
+	if opts.MaxKeys > 0 {
+		input.MaxKeys = aws.Int64(opts.MaxKeys)
+	}
+	if opts.StartAfter != "" {
+		// StartAfter is a real object key, unlike ContinuationToken which
+		// must be an opaque value previously returned by S3 itself.
+		input.StartAfter = aws.String(s.key(opts.StartAfter))
+	}
+
+	out, err := s.s3.ListObjectsV2WithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchBucket {
+		return nil, "", gostorages.ErrNotExist
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	entries := mergeListEntries(out.Contents, out.CommonPrefixes, s.stripPrefix)
+
+	var token string
+	if aws.BoolValue(out.IsTruncated) && len(entries) > 0 {
+		token = entries[len(entries)-1].Path
+	}
+	return entries, token, nil
+}
+
+// mergeListEntries merges contents and commonPrefixes, each already sorted
+// by key on their own, into S3's true lexicographic key order. Naively
+// appending commonPrefixes after contents would leave the combined list out
+// of order whenever a page mixes objects and "directories", corrupting both
+// the entries returned and any StartAfter token derived from the last one.
+func mergeListEntries(contents []*s3.Object, commonPrefixes []*s3.CommonPrefix, stripPrefix func(string) string) []gostorages.Entry {
+	type keyedEntry struct {
+		key   string
+		entry gostorages.Entry
+	}
+	keyed := make([]keyedEntry, 0, len(contents)+len(commonPrefixes))
+	for _, obj := range contents {
+		keyed = append(keyed, keyedEntry{
+			key: *obj.Key,
+			entry: gostorages.Entry{
+				Path:         stripPrefix(*obj.Key),
+				Size:         *obj.Size,
+				ModifiedTime: *obj.LastModified,
+			},
+		})
+	}
+	for _, common := range commonPrefixes {
+		keyed = append(keyed, keyedEntry{
+			key: *common.Prefix,
+			entry: gostorages.Entry{
+				Path:     stripPrefix(*common.Prefix),
+				IsPrefix: true,
+			},
+		})
+	}
+	sort.Slice(keyed, func(i, j int) bool { return keyed[i].key < keyed[j].key })
+
+	entries := make([]gostorages.Entry, len(keyed))
+	for i, k := range keyed {
+		entries[i] = k.entry
+	}
+	return entries
+}
+
 // OpenWithStat opens path for reading with file stats.
 func (s *Storage) OpenWithStat(ctx context.Context, path string) (io.ReadCloser, *gostorages.Stat, error) {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Key:    aws.String(path),
+		Key:    aws.String(s.key(path)),
 	}
 	out, err := s.s3.GetObjectWithContext(ctx, input)
 	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
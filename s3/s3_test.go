@@ -0,0 +1,44 @@
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestMergeListEntries(t *testing.T) {
+	now := time.Now()
+	contents := []*s3.Object{
+		{Key: aws.String("zebra.txt"), Size: aws.Int64(1), LastModified: aws.Time(now)},
+		{Key: aws.String("zz_last.txt"), Size: aws.Int64(2), LastModified: aws.Time(now)},
+	}
+	commonPrefixes := []*s3.CommonPrefix{
+		{Prefix: aws.String("dir/")},
+	}
+
+	entries := mergeListEntries(contents, commonPrefixes, func(key string) string { return key })
+
+	want := []string{"dir/", "zebra.txt", "zz_last.txt"}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, path := range want {
+		if entries[i].Path != path {
+			t.Errorf("entry %d: expected %q, got %q", i, path, entries[i].Path)
+		}
+	}
+	if !entries[0].IsPrefix {
+		t.Errorf("expected %q to be a prefix entry", entries[0].Path)
+	}
+
+	// The bug this guards against: taking the last element of contents and
+	// commonPrefixes concatenated naively would put "dir/" last instead of
+	// first, and a StartAfter token derived from it would make the next
+	// page repeat zebra.txt.
+	last := entries[len(entries)-1].Path
+	if last != "zz_last.txt" {
+		t.Errorf("expected last entry (StartAfter token source) to be %q, got %q", "zz_last.txt", last)
+	}
+}
@@ -0,0 +1,63 @@
+package gostorages
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Storage is the storage interface.
+type Storage interface {
+	Save(ctx context.Context, content io.Reader, path string) error
+	Stat(ctx context.Context, path string) (*Stat, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	OpenWithStat(ctx context.Context, path string) (io.ReadCloser, *Stat, error)
+	Delete(ctx context.Context, path string) error
+}
+
+// Stat contains metadata about content stored in storage.
+type Stat struct {
+	ModifiedTime time.Time
+	Size         int64
+}
+
+// ErrNotExist is a sentinel error returned by the Open and the Stat methods.
+var ErrNotExist = errors.New("does not exist")
+
+// Entry describes an object discovered while listing a storage backend.
+type Entry struct {
+	// Path is the entry key, relative to the storage root.
+	Path string
+	// Size is the entry size in bytes. It is zero for common prefixes
+	// returned when ListOptions.Recursive is false.
+	Size int64
+	// ModifiedTime is the last modification time of the entry. It is the
+	// zero value for common prefixes.
+	ModifiedTime time.Time
+	// IsPrefix reports whether this entry is a common prefix ("directory")
+	// rather than an object, as returned when ListOptions.Recursive is false.
+	IsPrefix bool
+}
+
+// ListOptions configures a Lister.List call.
+type ListOptions struct {
+	// MaxKeys limits the number of entries returned by a single call. A
+	// zero value lets the backend pick its own default page size.
+	MaxKeys int64
+	// Recursive lists every object under prefix. When false, entries are
+	// only listed down to the next path separator, and intermediate
+	// "directories" are returned as entries with IsPrefix set.
+	Recursive bool
+	// StartAfter resumes a listing after the given key, or after the
+	// continuation token returned by a previous List call.
+	StartAfter string
+}
+
+// Lister is implemented by storage backends that can enumerate the
+// entries stored under a given prefix. It returns the matching entries
+// along with a continuation token to pass as ListOptions.StartAfter to
+// fetch the next page, or an empty string once the listing is exhausted.
+type Lister interface {
+	List(ctx context.Context, prefix string, opts ListOptions) ([]Entry, string, error)
+}